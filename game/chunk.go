@@ -0,0 +1,81 @@
+package game
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Chunk is a thin coordinator over its store (block data) and renderer
+// (GPU mesh). Generation and persistence are driven externally by the
+// ChunkLoader; Buffer still builds the mesh and uploads it synchronously
+// on whichever goroutine calls it (today, always the GL thread, via
+// ChunkLoader.Spawn or a queued World.ProcessTasks task). Mesh building
+// reads neighboring chunks through World.Block, which can spawn a chunk
+// (and its GL-owned renderer) as a side effect, so Buffer isn't safe to
+// move off the GL thread without addressing that first.
+type Chunk struct {
+	pos mgl32.Vec3
+
+	// world the chunk belongs to, used by the mesher to sample blocks
+	// across chunk boundaries for face culling and ambient occlusion
+	world *World
+
+	store    *ChunkStore
+	renderer *ChunkRenderer
+}
+
+// newChunk builds a chunk at pos. shader is nil on a headless server,
+// which skips renderer creation entirely so no GL context is required;
+// Buffer/Draw/Destroy become no-ops in that case.
+func newChunk(shader *Shader, atlas *TextureAtlas, pos mgl32.Vec3, world *World) *Chunk {
+	c := &Chunk{pos: pos, world: world}
+	if shader != nil {
+		c.renderer = newChunkRenderer(shader, atlas)
+	}
+	c.store = newChunkStore(c)
+	return c
+}
+
+// Init seeds the chunk's blocks from generated or loaded terrain.
+func (c *Chunk) Init(terrain *TerrainData) {
+	c.store.Load(terrain)
+}
+
+// Block returns the block at the given in-chunk offset.
+func (c *Chunk) Block(x, y, z int) *Block {
+	return c.store.Get(x, y, z)
+}
+
+// Dirty reports whether the chunk has diverged from its last known baseline.
+func (c *Chunk) Dirty() bool {
+	return c.store.Dirty()
+}
+
+// ClearDirty marks the chunk as matching its last known baseline, e.g.
+// after a successful flush or a reload that already reflects disk state.
+func (c *Chunk) ClearDirty() {
+	c.store.ClearDirty()
+}
+
+// Buffer rebuilds the chunk's mesh and uploads it to the GPU. No-op on a
+// headless server, which has no renderer to upload to.
+func (c *Chunk) Buffer() {
+	if c.renderer == nil {
+		return
+	}
+	mesh := buildMesh(c, c.world)
+	c.renderer.Upload(mesh)
+}
+
+// Draw renders the chunk, highlighting target if it belongs to this chunk.
+func (c *Chunk) Draw(target *TargetBlock, camera *Camera, light *Light) {
+	if c.renderer == nil {
+		return
+	}
+	c.renderer.Draw(c.pos, target, camera, light)
+}
+
+// Destroy releases the chunk's GPU resources.
+func (c *Chunk) Destroy() {
+	if c.renderer == nil {
+		return
+	}
+	c.renderer.Destroy()
+}
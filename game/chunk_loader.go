@@ -0,0 +1,68 @@
+package game
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// ChunkLoader drives a chunk's lifecycle beyond the initial spawn:
+// generation, save-file load, decoration and persistence. save is nil for
+// a World with no save subsystem (see World.Load) - e.g. a Client's
+// World, which is never driven by a generator or persisted to disk in
+// the first place - and Spawn/Flush both treat that as "nothing to load,
+// nothing to persist" rather than requiring callers to check first.
+// Decoration and the mesh rebuild it triggers are queued through the
+// world's TaskQueue rather than run inline in Spawn, but they still run
+// on whatever goroutine drains that queue - today, the GL thread, via
+// World.ProcessTasks - not off it; Buffer() itself is still synchronous.
+type ChunkLoader struct {
+	generator *WorldGenerator
+	save      *WorldSave
+	tasks     *TaskQueue
+	world     *World
+}
+
+func newChunkLoader(generator *WorldGenerator, save *WorldSave, tasks *TaskQueue, world *World) *ChunkLoader {
+	return &ChunkLoader{generator: generator, save: save, tasks: tasks, world: world}
+}
+
+// Spawn builds a new chunk at pos: generates terrain synchronously (so
+// callers immediately have a walkable chunk), applies any persisted diff
+// on top, then - only if nothing was persisted - queues decoration and
+// the mesh rebuild it triggers. A chunk reloaded from disk already
+// reflects whatever decoration ran before it was saved, so decorate
+// never runs twice over the same chunk.
+func (l *ChunkLoader) Spawn(shader *Shader, atlas *TextureAtlas, pos mgl32.Vec3, decorate func(*Chunk)) *Chunk {
+	c := newChunk(shader, atlas, pos, l.world)
+	c.Init(l.generator.Terrain(pos))
+
+	if l.save != nil && l.save.Load(c) {
+		// matches the persisted baseline exactly - nothing new to flush
+		c.ClearDirty()
+	} else {
+		l.tasks.Queue(func() {
+			decorate(c)
+			c.Buffer()
+		})
+	}
+	c.Buffer()
+
+	return c
+}
+
+// Flush persists a chunk if it has diverged from the generator baseline.
+// A no-op when the loader has no save subsystem to persist to.
+func (l *ChunkLoader) Flush(c *Chunk) error {
+	if l.save == nil || !c.Dirty() {
+		return nil
+	}
+	return l.save.Flush(c, l.generator)
+}
+
+// SpawnRemote builds a chunk directly from terrain received over the
+// network, skipping generation and save-file lookup entirely. Used by a
+// client, which has no WorldGenerator of its own and treats the server
+// as the single source of truth for block data.
+func (l *ChunkLoader) SpawnRemote(shader *Shader, atlas *TextureAtlas, pos mgl32.Vec3, terrain *TerrainData) *Chunk {
+	c := newChunk(shader, atlas, pos, l.world)
+	c.Init(terrain)
+	c.Buffer()
+	return c
+}
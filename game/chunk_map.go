@@ -0,0 +1,83 @@
+package game
+
+import "sync"
+
+// chunkEntry is one occupied slot of a ChunkMap bucket.
+type chunkEntry struct {
+	pos   ChunkPos
+	chunk *Chunk
+}
+
+// ChunkMap is the spatial lookup for loaded chunks, replacing the
+// mgl32.Vec3-keyed VecMap[Chunk]. Rather than handing a ChunkPos struct
+// to Go's generic map (which hashes it generically, key-type by
+// key-type), it buckets entries under ChunkPos.hash() - a single
+// pre-mixed uint64 - the same trick nohash-hasher uses for integer keys
+// in voxel physics engines: the expensive part of hashing is done once,
+// by us, in a way suited to the key, instead of generically by the
+// runtime on every lookup.
+type ChunkMap struct {
+	mu    sync.RWMutex
+	table map[uint64][]chunkEntry
+}
+
+func newChunkMap() *ChunkMap {
+	return &ChunkMap{table: make(map[uint64][]chunkEntry)}
+}
+
+// Get returns the chunk at pos, or nil if none is loaded there.
+func (m *ChunkMap) Get(pos ChunkPos) *Chunk {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, e := range m.table[pos.hash()] {
+		if e.pos == pos {
+			return e.chunk
+		}
+	}
+	return nil
+}
+
+// Set registers a chunk under pos.
+func (m *ChunkMap) Set(pos ChunkPos, c *Chunk) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := pos.hash()
+	bucket := m.table[h]
+	for i, e := range bucket {
+		if e.pos == pos {
+			bucket[i].chunk = c
+			return
+		}
+	}
+	m.table[h] = append(bucket, chunkEntry{pos, c})
+}
+
+// Delete removes the chunk at pos, if any.
+func (m *ChunkMap) Delete(pos ChunkPos) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := pos.hash()
+	bucket := m.table[h]
+	for i, e := range bucket {
+		if e.pos == pos {
+			m.table[h] = append(bucket[:i], bucket[i+1:]...)
+			return
+		}
+	}
+}
+
+// All returns every currently loaded chunk.
+func (m *ChunkMap) All() []*Chunk {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]*Chunk, 0, len(m.table))
+	for _, bucket := range m.table {
+		for _, e := range bucket {
+			out = append(out, e.chunk)
+		}
+	}
+	return out
+}
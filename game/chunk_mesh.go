@@ -0,0 +1,365 @@
+package game
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// cube face directions, matching the 6 possible exposed faces a block
+// can have. The index into this table is what gets packed as the
+// per-vertex normal byte. Faces are paired by axis: 0/1 are the +/-x
+// faces, 2/3 are +/-y, 4/5 are +/-z.
+var faceNormals = [6][3]float32{
+	{1, 0, 0}, {-1, 0, 0},
+	{0, 1, 0}, {0, -1, 0},
+	{0, 0, 1}, {0, 0, -1},
+}
+
+var faceOffsets = [6][3]int{
+	{1, 0, 0}, {-1, 0, 0},
+	{0, 1, 0}, {0, -1, 0},
+	{0, 0, 1}, {0, 0, -1},
+}
+
+// axisDims are the chunk's extents indexed by axis (0=x, 1=y, 2=z).
+var axisDims = [3]int{chunkWidth, chunkHeight, chunkWidth}
+
+// sliceAxes gives the two axes tangent to the face plane for a given
+// normal axis, in ascending order; this is also the corner-coordinate
+// order cubeFaceCorners uses for that axis.
+var sliceAxes = [3][2]int{
+	{1, 2}, // normal axis x -> tangents y, z
+	{0, 2}, // normal axis y -> tangents x, z
+	{0, 1}, // normal axis z -> tangents x, y
+}
+
+// vertexSize is the packed per-vertex footprint in bytes: 4 bytes of
+// position+normal, 4 bytes of light (ao, sky, block, torch), 3 bytes of
+// tint (+1 pad byte), 4 bytes of atlas tile data. That's ~16 bytes
+// against the ~40+ of the old float attributes.
+const vertexSize = 16
+
+// atlasColumns/atlasRows describe atlas.png's tile grid, used to turn a
+// tile index back into a UV rect in the fragment shader.
+const (
+	atlasColumns = 16
+	atlasRows    = 16
+)
+
+// atlasTiles maps a block type to its tile index in the atlas texture,
+// row-major from the top-left - the same grid TextureAtlas slices
+// atlas.png into. Unknown block types fall back to tile 0 rather than
+// failing meshing over a missing entry.
+var atlasTiles = map[string]byte{
+	"grass":      0,
+	"dirt":       1,
+	"stone":      2,
+	"wood":       3,
+	"dark-wood":  4,
+	"white-wood": 5,
+	"leaves":     6,
+	"cactus":     7,
+}
+
+func atlasTile(blockType string) byte {
+	return atlasTiles[blockType]
+}
+
+// maskCell is one slot of a face-direction's 2D mesh mask: everything
+// that has to match between two block faces for greedy meshing to merge
+// them into a single quad.
+type maskCell struct {
+	blockType   string
+	translucent bool
+	tile        byte    // atlas tile index, derived from blockType
+	ao          [4]byte // per-corner ambient occlusion, 0-3
+	light       [3]byte // sky, block, torch, uniform across a chunk for now
+}
+
+func (a *maskCell) equals(b *maskCell) bool {
+	return a.blockType == b.blockType &&
+		a.translucent == b.translucent &&
+		a.ao == b.ao &&
+		a.light == b.light
+}
+
+// buildMesh meshes every exposed face of chunk's blocks. For each of the
+// 6 face directions it sweeps the chunk slice by slice, builds a 2D mask
+// of (blockType, ao, light) tuples, then greedily merges equal tuples
+// into the largest axis-aligned rectangle it can, emitting one quad per
+// rectangle instead of one per block face. Slices containing translucent
+// blocks (leaves) fall back to a per-block quad, since merging those
+// would change how they're drawn. world is used to sample across chunk
+// boundaries; it may be nil (e.g. in tests), in which case boundary
+// faces are always treated as exposed.
+func buildMesh(chunk *Chunk, world *World) []byte {
+	var verts []byte
+
+	for face := 0; face < 6; face++ {
+		axis := face / 2
+		tangents := sliceAxes[axis]
+		dimA := axisDims[axis]
+		dimU := axisDims[tangents[0]]
+		dimV := axisDims[tangents[1]]
+
+		for layer := 0; layer < dimA; layer++ {
+			mask := buildMask(chunk, world, axis, layer, face, tangents, dimU, dimV)
+			verts = greedyEmit(verts, mask, dimU, dimV, axis, layer, face, tangents)
+		}
+	}
+
+	return verts
+}
+
+func buildMask(chunk *Chunk, world *World, axis, layer, face int, tangents [2]int, dimU, dimV int) []*maskCell {
+	mask := make([]*maskCell, dimU*dimV)
+	off := faceOffsets[face]
+
+	for u := 0; u < dimU; u++ {
+		for v := 0; v < dimV; v++ {
+			c := coordFor(axis, layer, tangents, u, v)
+			active, blockType := blockAt(chunk, world, c[0], c[1], c[2])
+			if !active {
+				continue
+			}
+
+			nActive, _ := blockAt(chunk, world, c[0]+off[0], c[1]+off[1], c[2]+off[2])
+			if nActive {
+				continue // hidden behind a neighboring solid block
+			}
+
+			cell := &maskCell{
+				blockType:   blockType,
+				translucent: blockType == "leaves",
+				tile:        atlasTile(blockType),
+				light:       [3]byte{255, 255, 255},
+			}
+			for k := 0; k < 4; k++ {
+				cell.ao[k] = cornerAO(chunk, world, c[0], c[1], c[2], face, k)
+			}
+			mask[u*dimV+v] = cell
+		}
+	}
+
+	return mask
+}
+
+// greedyEmit merges adjacent equal mask cells into rectangles and emits
+// one quad per rectangle. A slice containing any translucent cell is not
+// merged at all: every face in it gets its own quad.
+func greedyEmit(verts []byte, mask []*maskCell, dimU, dimV, axis, layer, face int, tangents [2]int) []byte {
+	visited := make([]bool, dimU*dimV)
+
+	perBlock := false
+	for _, cell := range mask {
+		if cell != nil && cell.translucent {
+			perBlock = true
+			break
+		}
+	}
+
+	for u := 0; u < dimU; u++ {
+		for v := 0; v < dimV; v++ {
+			idx := u*dimV + v
+			cell := mask[idx]
+			if cell == nil || visited[idx] {
+				continue
+			}
+
+			if perBlock {
+				visited[idx] = true
+				verts = emitQuad(verts, axis, layer, face, tangents, u, v, 1, 1, cell)
+				continue
+			}
+
+			// grow along v while the next cell matches
+			spanV := 1
+			for v+spanV < dimV {
+				n := mask[u*dimV+(v+spanV)]
+				if n == nil || visited[u*dimV+(v+spanV)] || n.translucent || !n.equals(cell) {
+					break
+				}
+				spanV++
+			}
+
+			// grow along u while the whole row still matches
+			spanU := 1
+		growU:
+			for u+spanU < dimU {
+				for k := 0; k < spanV; k++ {
+					n := mask[(u+spanU)*dimV+(v+k)]
+					if n == nil || visited[(u+spanU)*dimV+(v+k)] || n.translucent || !n.equals(cell) {
+						break growU
+					}
+				}
+				spanU++
+			}
+
+			for du := 0; du < spanU; du++ {
+				for dv := 0; dv < spanV; dv++ {
+					visited[(u+du)*dimV+(v+dv)] = true
+				}
+			}
+
+			verts = emitQuad(verts, axis, layer, face, tangents, u, v, spanU, spanV, cell)
+		}
+	}
+
+	return verts
+}
+
+// coordFor maps a (layer, u, v) position in a face's 2D slice back to a
+// 3D in-chunk coordinate.
+func coordFor(axis, layer int, tangents [2]int, u, v int) [3]int {
+	var c [3]int
+	c[axis] = layer
+	c[tangents[0]] = u
+	c[tangents[1]] = v
+	return c
+}
+
+// blockAt returns whether the block at the given in-chunk coordinate is
+// active, consulting the owning world for coordinates that fall outside
+// this chunk so faces and AO at chunk boundaries account for neighbors.
+func blockAt(chunk *Chunk, world *World, x, y, z int) (active bool, blockType string) {
+	if inBounds(x, y, z) {
+		b := chunk.Block(x, y, z)
+		return b.Active(), b.BlockType()
+	}
+	if world == nil {
+		return false, ""
+	}
+	pos := chunk.pos.Add(mgl32.Vec3{float32(x), float32(y), float32(z)})
+	b := world.Block(pos)
+	if b == nil {
+		return false, ""
+	}
+	return b.Active(), b.BlockType()
+}
+
+func inBounds(x, y, z int) bool {
+	return x >= 0 && x < chunkWidth && y >= 0 && y < chunkHeight && z >= 0 && z < chunkWidth
+}
+
+// cornerAO computes the ambient occlusion (0-3, lower is darker) for one
+// corner of the given block face: it samples the two edge neighbors and
+// the diagonal corner neighbor in the face's plane, one step out along
+// the normal. Two solid edge neighbors fully occlude the corner
+// regardless of the diagonal, matching the classic voxel AO trick.
+func cornerAO(chunk *Chunk, world *World, x, y, z, face, cornerIdx int) byte {
+	axis := face / 2
+	tangents := sliceAxes[axis]
+	off := faceOffsets[face]
+	corner := cubeFaceCorners(face)[cornerIdx]
+
+	uSign := corner[tangents[0]]*2 - 1
+	vSign := corner[tangents[1]]*2 - 1
+
+	base := [3]int{x + off[0], y + off[1], z + off[2]}
+
+	uOff := base
+	uOff[tangents[0]] += uSign
+	vOff := base
+	vOff[tangents[1]] += vSign
+	cornerOff := uOff
+	cornerOff[tangents[1]] += vSign
+
+	side1, _ := blockAt(chunk, world, uOff[0], uOff[1], uOff[2])
+	side2, _ := blockAt(chunk, world, vOff[0], vOff[1], vOff[2])
+	diag, _ := blockAt(chunk, world, cornerOff[0], cornerOff[1], cornerOff[2])
+
+	return vertexAO(side1, side2, diag)
+}
+
+func vertexAO(side1, side2, corner bool) byte {
+	if side1 && side2 {
+		return 0
+	}
+	n := 0
+	if side1 {
+		n++
+	}
+	if side2 {
+		n++
+	}
+	if corner {
+		n++
+	}
+	return byte(3 - n)
+}
+
+// emitQuad packs two triangles (6 vertices) for a face spanning spanU x
+// spanV cells starting at (u,v) in the slice's tangent coordinates, and
+// appends them to verts.
+//
+// Per vertex layout (16 bytes):
+//
+//	byte 0-2:   chunk-local x, y, z (chunkWidth/chunkHeight both fit uint8)
+//	byte 3:     normal index, 0-5
+//	byte 4:     ambient occlusion, scaled from 0-3 to 0-255
+//	byte 5-7:   light: sky, block, torch (0-255, normalized in the shader)
+//	byte 8-11:  tint: r, g, b, pad (0-255, normalized in the shader)
+//	byte 12:    atlas tile index
+//	byte 13-14: atlas tile-local u, v, in repeat units (0 or the quad's
+//	            span along that axis) - the fragment shader fracs these
+//	            to tile the texture across a greedy-merged quad instead
+//	            of stretching one tile across it
+//	byte 15:    pad
+func emitQuad(verts []byte, axis, layer, face int, tangents [2]int, u, v, spanU, spanV int, cell *maskCell) []byte {
+	planeCoord := layer
+	if face%2 == 0 {
+		planeCoord = layer + 1
+	}
+
+	corners := cubeFaceCorners(face)
+	var pts [4][3]int
+	var tileUV [4][2]byte
+	for i, c := range corners {
+		var p [3]int
+		p[axis] = planeCoord
+		if c[tangents[0]] == 1 {
+			p[tangents[0]] = u + spanU
+			tileUV[i][0] = byte(spanU)
+		} else {
+			p[tangents[0]] = u
+			tileUV[i][0] = 0
+		}
+		if c[tangents[1]] == 1 {
+			p[tangents[1]] = v + spanV
+			tileUV[i][1] = byte(spanV)
+		} else {
+			p[tangents[1]] = v
+			tileUV[i][1] = 0
+		}
+		pts[i] = p
+	}
+
+	const aoScale = 85 // 3 * 85 == 255, so ao packs evenly into a byte
+	for _, idx := range [6]int{0, 1, 2, 0, 2, 3} {
+		p := pts[idx]
+		verts = append(verts,
+			byte(p[0]), byte(p[1]), byte(p[2]), byte(face),
+			cell.ao[idx]*aoScale, cell.light[0], cell.light[1], cell.light[2],
+			255, 255, 255, 0,
+			cell.tile, tileUV[idx][0], tileUV[idx][1], 0,
+		)
+	}
+	return verts
+}
+
+// cubeFaceCorners returns the 4 unit-cube corners of the given face, in
+// a consistent winding order. Each corner's tangent-axis components (see
+// sliceAxes) are 0 or 1, marking which edge of the face it sits on.
+func cubeFaceCorners(face int) [4][3]int {
+	switch face {
+	case 0: // +x
+		return [4][3]int{{1, 0, 0}, {1, 1, 0}, {1, 1, 1}, {1, 0, 1}}
+	case 1: // -x
+		return [4][3]int{{0, 0, 1}, {0, 1, 1}, {0, 1, 0}, {0, 0, 0}}
+	case 2: // +y
+		return [4][3]int{{0, 1, 0}, {0, 1, 1}, {1, 1, 1}, {1, 1, 0}}
+	case 3: // -y
+		return [4][3]int{{0, 0, 1}, {0, 0, 0}, {1, 0, 0}, {1, 0, 1}}
+	case 4: // +z
+		return [4][3]int{{1, 0, 1}, {1, 1, 1}, {0, 1, 1}, {0, 0, 1}}
+	default: // -z
+		return [4][3]int{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 0, 0}}
+	}
+}
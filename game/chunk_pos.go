@@ -0,0 +1,79 @@
+package game
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ChunkPos is a chunk-grid coordinate: which chunk, not which block.
+// Using integers here instead of a chunk's float mgl32.Vec3 origin means
+// two chunks at the "same" position can never diverge due to float
+// rounding, and lookups don't need to re-derive an integer coordinate
+// from a float every time.
+type ChunkPos struct {
+	X, Y, Z int32
+}
+
+// chunkPosFromOrigin converts a chunk's world-space origin - already
+// chunk-aligned, as SpawnChunk requires - into its ChunkPos.
+func chunkPosFromOrigin(pos mgl32.Vec3) ChunkPos {
+	return ChunkPos{
+		X: int32(pos.X()) / chunkWidth,
+		Y: int32(pos.Y()) / chunkHeight,
+		Z: int32(pos.Z()) / chunkWidth,
+	}
+}
+
+// Origin returns the chunk's world-space origin.
+func (p ChunkPos) Origin() mgl32.Vec3 {
+	return mgl32.Vec3{
+		float32(p.X) * chunkWidth,
+		float32(p.Y) * chunkHeight,
+		float32(p.Z) * chunkWidth,
+	}
+}
+
+// WorldToChunk takes any world-space position, including non-round ones,
+// and returns both the ChunkPos it falls in and its in-chunk block
+// offset, so callers that need both (World.Block, the physics
+// broadphase) only have to floor and divide once.
+func WorldToChunk(pos mgl32.Vec3) (ChunkPos, [3]int) {
+	floor := func(v float32) int { return int(math.Floor(float64(v))) }
+	x, y, z := floor(pos.X()), floor(pos.Y()), floor(pos.Z())
+
+	xoffset, yoffset, zoffset := x%chunkWidth, y%chunkHeight, z%chunkWidth
+	// if the offsets are negative we flip, because chunk origins are
+	// at the lower end corners
+	if xoffset < 0 {
+		xoffset = chunkWidth + xoffset
+	}
+	if yoffset < 0 {
+		yoffset = chunkHeight + yoffset
+	}
+	if zoffset < 0 {
+		zoffset = chunkWidth + zoffset
+	}
+
+	cp := ChunkPos{
+		X: int32((x - xoffset) / chunkWidth),
+		Y: int32((y - yoffset) / chunkHeight),
+		Z: int32((z - zoffset) / chunkWidth),
+	}
+	return cp, [3]int{xoffset, yoffset, zoffset}
+}
+
+// hash mixes the three axes into a single uint64, used as the ChunkMap
+// bucket key so lookups hash one machine word instead of a 12-byte
+// struct. The axes are combined with the 64-bit golden-ratio multiplier,
+// then finished with the first xor-shift/multiply/xor-shift rounds of
+// MurmurHash3's fmix64 finalizer.
+func (p ChunkPos) hash() uint64 {
+	h := uint64(uint32(p.X))
+	h = h*0x9E3779B97F4A7C15 + uint64(uint32(p.Y))
+	h = h*0x9E3779B97F4A7C15 + uint64(uint32(p.Z))
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	return h
+}
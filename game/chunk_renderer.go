@@ -0,0 +1,76 @@
+package game
+
+import (
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ChunkRenderer owns a chunk's GPU-side mesh: the VAO/VBO and whatever
+// vertex data Upload last received. It never touches block data
+// directly; Upload must still run on the GL thread, and today Buffer()
+// calls it synchronously right after building the mesh.
+type ChunkRenderer struct {
+	shader *Shader
+	atlas  *TextureAtlas
+
+	vao, vbo uint32
+	vertices int32
+}
+
+func newChunkRenderer(shader *Shader, atlas *TextureAtlas) *ChunkRenderer {
+	r := &ChunkRenderer{shader: shader, atlas: atlas}
+	gl.GenVertexArrays(1, &r.vao)
+	gl.GenBuffers(1, &r.vbo)
+	return r
+}
+
+// Upload uploads mesh data built by Chunk.Buffer. Must run on the GL thread.
+//
+// The buffer is packed per vertexSize (see chunk_mesh.go): position+normal
+// are read as unnormalized integers so the shader can index into a normal
+// table and reconstruct chunk-local coordinates exactly, while light and
+// tint are read as normalized unsigned bytes so the shader sees them as
+// 0..1 floats without a CPU-side conversion pass.
+func (r *ChunkRenderer) Upload(mesh []byte) {
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(mesh), gl.Ptr(mesh), gl.DYNAMIC_DRAW)
+
+	stride := int32(vertexSize)
+	gl.VertexAttribIPointer(0, 4, gl.UNSIGNED_BYTE, stride, gl.PtrOffset(0))
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(1, 4, gl.UNSIGNED_BYTE, true, stride, gl.PtrOffset(4))
+	gl.EnableVertexAttribArray(1)
+	gl.VertexAttribPointer(2, 4, gl.UNSIGNED_BYTE, true, stride, gl.PtrOffset(8))
+	gl.EnableVertexAttribArray(2)
+	gl.VertexAttribIPointer(3, 4, gl.UNSIGNED_BYTE, stride, gl.PtrOffset(12))
+	gl.EnableVertexAttribArray(3)
+
+	r.vertices = int32(len(mesh) / vertexSize)
+}
+
+// Draw binds the chunk's shader and issues the draw call, highlighting
+// target if it belongs to this chunk.
+func (r *ChunkRenderer) Draw(pos mgl32.Vec3, target *TargetBlock, camera *Camera, light *Light) {
+	r.shader.Use()
+	r.shader.SetVec3("chunkPos", pos)
+	r.atlas.Bind()
+	r.shader.SetVec2("atlasGrid", mgl32.Vec2{float32(atlasColumns), float32(atlasRows)})
+	camera.Apply(r.shader)
+	light.Apply(r.shader)
+	if target != nil {
+		r.shader.SetInt("hasTarget", 1)
+		r.shader.SetVec3("targetBlockPos", target.block.WorldPos())
+	} else {
+		r.shader.SetInt("hasTarget", 0)
+	}
+
+	gl.BindVertexArray(r.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, r.vertices)
+}
+
+// Destroy releases the chunk's GPU resources.
+func (r *ChunkRenderer) Destroy() {
+	gl.DeleteVertexArrays(1, &r.vao)
+	gl.DeleteBuffers(1, &r.vbo)
+}
@@ -0,0 +1,153 @@
+package game
+
+import (
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// chunk dimensions, in blocks.
+const (
+	chunkWidth  = 16
+	chunkHeight = 200
+)
+
+// Block is a single voxel inside a chunk. Callers (tree decoration,
+// player interaction, physics) hold on to the pointer and mutate it in
+// place; ChunkStore only owns the backing array and offset bookkeeping.
+type Block struct {
+	chunk *Chunk
+
+	// offset inside the owning chunk
+	x, y, z int
+
+	active    bool
+	blockType string
+}
+
+// WorldPos returns the block's position in world space.
+func (b *Block) WorldPos() mgl32.Vec3 {
+	return b.chunk.pos.Add(mgl32.Vec3{float32(b.x), float32(b.y), float32(b.z)})
+}
+
+// Active reports whether the block is currently solid, holding the
+// owning store's lock across the read so it can't observe a half-written
+// block from a concurrent Set.
+func (b *Block) Active() bool {
+	s := b.chunk.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return b.active
+}
+
+// BlockType reports the block's current type, under the same lock as Active.
+func (b *Block) BlockType() string {
+	s := b.chunk.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return b.blockType
+}
+
+// Set updates the block's state in place and marks its chunk dirty. It
+// delegates to ChunkStore.Set rather than writing the fields itself, so
+// the mutation is covered by the store's lock - not just the dirty flag -
+// and concurrent readers (Active, BlockType) never see a torn update.
+func (b *Block) Set(active bool, blockType string) {
+	b.chunk.store.Set(b.x, b.y, b.z, active, blockType)
+}
+
+// TerrainData is the block grid produced by WorldGenerator.Terrain (or
+// reconstructed from a save file), consumed by ChunkStore.Load to seed a
+// freshly spawned chunk.
+type TerrainData struct {
+	Blocks [chunkWidth][chunkHeight][chunkWidth]struct {
+		Active    bool
+		BlockType string
+	}
+}
+
+// ChunkStore owns a chunk's block array: thread-safe access plus a dirty
+// flag, so the loader can tell whether there's anything worth
+// persisting without diffing the whole array. "Thread-safe" means going
+// through Get+Block.Active/BlockType or Set - a *Block returned by Get
+// still shares state with every other holder of the pointer, so reading
+// its fields directly, in-package, bypasses the lock.
+type ChunkStore struct {
+	mu     sync.RWMutex
+	blocks [chunkWidth][chunkHeight][chunkWidth]*Block
+	dirty  bool
+}
+
+func newChunkStore(owner *Chunk) *ChunkStore {
+	s := &ChunkStore{}
+	for x := 0; x < chunkWidth; x++ {
+		for y := 0; y < chunkHeight; y++ {
+			for z := 0; z < chunkWidth; z++ {
+				s.blocks[x][y][z] = &Block{chunk: owner, x: x, y: y, z: z}
+			}
+		}
+	}
+	return s
+}
+
+// Load seeds every block from terrain data and clears the dirty flag,
+// since this reflects a known baseline (generated or freshly loaded).
+func (s *ChunkStore) Load(terrain *TerrainData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for x := 0; x < chunkWidth; x++ {
+		for y := 0; y < chunkHeight; y++ {
+			for z := 0; z < chunkWidth; z++ {
+				b := terrain.Blocks[x][y][z]
+				s.blocks[x][y][z].active = b.Active
+				s.blocks[x][y][z].blockType = b.BlockType
+			}
+		}
+	}
+	s.dirty = false
+}
+
+// Get returns the block at the given in-chunk offset.
+func (s *ChunkStore) Get(x, y, z int) *Block {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.blocks[x][y][z]
+}
+
+// Set replaces a block's state in place and marks the chunk dirty.
+func (s *ChunkStore) Set(x, y, z int, active bool, blockType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.blocks[x][y][z]
+	b.active = active
+	b.blockType = blockType
+	s.dirty = true
+}
+
+// Dirty reports whether any block has changed since Load.
+func (s *ChunkStore) Dirty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dirty
+}
+
+// MarkDirty flags the store as changed, for callers that mutate blocks
+// returned by Get directly instead of going through Set.
+func (s *ChunkStore) MarkDirty() {
+	s.mu.Lock()
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// ClearDirty clears the dirty flag, e.g. after a successful flush to
+// disk or a reload that already reflects the persisted baseline.
+func (s *ChunkStore) ClearDirty() {
+	s.mu.Lock()
+	s.dirty = false
+	s.mu.Unlock()
+}
+
+// All returns the raw block array, for meshing and diffing.
+func (s *ChunkStore) All() *[chunkWidth][chunkHeight][chunkWidth]*Block {
+	return &s.blocks
+}
@@ -0,0 +1,91 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// encodeBlockGrid run-length encodes a chunk's block grid for
+// transmission over the network: consecutive blocks sharing the same
+// (active, blockType) collapse into a single run, since most of a
+// chunk's volume is uniform (air, or a stone layer). gnet.ChunkData.Encode
+// zlib-compresses the result on top, same as region file payloads.
+func encodeBlockGrid(chunk *Chunk) []byte {
+	var buf bytes.Buffer
+	var runActive bool
+	var runType string
+	runLen := uint32(0)
+
+	flush := func() {
+		if runLen == 0 {
+			return
+		}
+		binary.Write(&buf, binary.LittleEndian, runLen)
+		active := byte(0)
+		if runActive {
+			active = 1
+		}
+		buf.WriteByte(active)
+		binary.Write(&buf, binary.LittleEndian, uint16(len(runType)))
+		buf.WriteString(runType)
+	}
+
+	for x := 0; x < chunkWidth; x++ {
+		for y := 0; y < chunkHeight; y++ {
+			for z := 0; z < chunkWidth; z++ {
+				b := chunk.Block(x, y, z)
+				active, blockType := b.Active(), b.BlockType()
+				if runLen > 0 && active == runActive && blockType == runType {
+					runLen++
+					continue
+				}
+				flush()
+				runActive, runType, runLen = active, blockType, 1
+			}
+		}
+	}
+	flush()
+
+	return buf.Bytes()
+}
+
+// decodeBlockGrid reverses encodeBlockGrid into a TerrainData ready for
+// ChunkStore.Load, the same entry point used for generated or save-file
+// terrain.
+func decodeBlockGrid(data []byte) *TerrainData {
+	terrain := &TerrainData{}
+	r := bytes.NewReader(data)
+	x, y, z := 0, 0, 0
+
+	advance := func() {
+		z++
+		if z == chunkWidth {
+			z = 0
+			y++
+		}
+		if y == chunkHeight {
+			y = 0
+			x++
+		}
+	}
+
+	for r.Len() > 0 && x < chunkWidth {
+		var runLen uint32
+		binary.Read(r, binary.LittleEndian, &runLen)
+		activeByte, _ := r.ReadByte()
+		var typeLen uint16
+		binary.Read(r, binary.LittleEndian, &typeLen)
+		typeBuf := make([]byte, typeLen)
+		r.Read(typeBuf)
+
+		active := activeByte != 0
+		blockType := string(typeBuf)
+		for n := uint32(0); n < runLen && x < chunkWidth; n++ {
+			terrain.Blocks[x][y][z].Active = active
+			terrain.Blocks[x][y][z].BlockType = blockType
+			advance()
+		}
+	}
+
+	return terrain
+}
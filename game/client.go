@@ -0,0 +1,185 @@
+package game
+
+import (
+	"log"
+	"net"
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	gnet "github.com/nasa03/minecraft/game/net"
+)
+
+// Client owns the rendering-side World for a connection to a Server,
+// authoritative or in-process. Its World is never driven by a
+// WorldGenerator: chunks arrive as ChunkData packets and get injected
+// through ChunkLoader.SpawnRemote, and block edits arrive as BlockSet
+// packets, same as a save-file reload but pushed continuously instead
+// of read once.
+type Client struct {
+	conn     net.Conn
+	world    *World
+	playerID uint32
+
+	mu             sync.Mutex
+	remoteEntities map[uint32]mgl32.Vec3
+}
+
+// newClient completes a handshake over conn and starts the background
+// read loop that applies incoming world state to a fresh World.
+func newClient(conn net.Conn, shader *Shader, atlas *TextureAtlas, playerName string) (*Client, error) {
+	if err := gnet.WritePacket(conn, gnet.PacketHandshake, gnet.Handshake{
+		ProtocolVersion: gnet.ProtocolVersion,
+		PlayerName:      playerName,
+	}.Encode()); err != nil {
+		return nil, err
+	}
+
+	t, payload, err := gnet.ReadPacket(conn)
+	if err != nil {
+		return nil, err
+	}
+	if t == gnet.PacketHandshake {
+		if _, err := gnet.DecodeHandshake(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Client{
+		conn:           conn,
+		world:          newWorld(shader, atlas),
+		remoteEntities: make(map[uint32]mgl32.Vec3),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// RemoteEntities returns the last known position of every other entity
+// (currently, only other players) the server has announced. There's no
+// renderable remote-entity type in this tree yet, so this is as far as
+// the client takes it for now.
+func (c *Client) RemoteEntities() map[uint32]mgl32.Vec3 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[uint32]mgl32.Vec3, len(c.remoteEntities))
+	for id, pos := range c.remoteEntities {
+		out[id] = pos
+	}
+	return out
+}
+
+// SendMove reports the local player's position and velocity. The server
+// applies only the (clamped) velocity to its own body for this player and
+// ignores the reported position; the chunks it streams follow that
+// server-side body.
+func (c *Client) SendMove(pos, vel mgl32.Vec3) {
+	payload := gnet.PlayerMove{
+		PlayerID: c.playerID,
+		Pos:      [3]float32{pos.X(), pos.Y(), pos.Z()},
+		Vel:      [3]float32{vel.X(), vel.Y(), vel.Z()},
+	}.Encode()
+	if err := gnet.WritePacket(c.conn, gnet.PacketPlayerMove, payload); err != nil {
+		log.Println("client: send move:", err)
+	}
+}
+
+// SendBlockSet reports a local block edit so the server can apply and
+// rebroadcast it.
+func (c *Client) SendBlockSet(pos mgl32.Vec3, active bool, blockType string) {
+	payload := gnet.BlockSet{
+		X: int32(pos.X()), Y: int32(pos.Y()), Z: int32(pos.Z()),
+		Active: active, BlockType: blockType,
+	}.Encode()
+	if err := gnet.WritePacket(c.conn, gnet.PacketBlockSet, payload); err != nil {
+		log.Println("client: send block set:", err)
+	}
+}
+
+func (c *Client) readLoop() {
+	for {
+		t, payload, err := gnet.ReadPacket(c.conn)
+		if err != nil {
+			log.Println("client: connection closed:", err)
+			return
+		}
+		c.handlePacket(t, payload)
+	}
+}
+
+func (c *Client) handlePacket(t gnet.PacketType, payload []byte) {
+	switch t {
+	case gnet.PacketChunkData:
+		data, err := gnet.DecodeChunkData(payload)
+		if err != nil {
+			log.Println("client: decode chunk:", err)
+			return
+		}
+		pos := mgl32.Vec3{float32(data.ChunkX), float32(data.ChunkY), float32(data.ChunkZ)}
+		terrain := decodeBlockGrid(data.Blocks)
+		// SpawnRemote creates the renderer and uploads the mesh, so it runs
+		// as a queued task on the GL thread (World.ProcessTasks)
+		c.world.tasks.Queue(func() {
+			chunk := c.world.loader.SpawnRemote(c.world.chunkShader, c.world.atlas, pos, terrain)
+			c.world.chunks.Set(chunkPosFromOrigin(pos), chunk)
+		})
+
+	case gnet.PacketBlockSet:
+		set, err := gnet.DecodeBlockSet(payload)
+		if err != nil {
+			log.Println("client: decode block set:", err)
+			return
+		}
+		// Only apply to chunks we've already received. The server applies
+		// the edit to its own copy before relaying it, so a chunk we
+		// haven't been streamed yet already carries it. Queued behind any
+		// pending chunk spawns so it lands after the chunk it belongs to.
+		pos := mgl32.Vec3{float32(set.X), float32(set.Y), float32(set.Z)}
+		c.world.tasks.Queue(func() {
+			if b := c.localBlock(pos); b != nil {
+				b.Set(set.Active, set.BlockType)
+			}
+		})
+
+	case gnet.PacketPlayerMove:
+		move, err := gnet.DecodePlayerMove(payload)
+		if err != nil {
+			log.Println("client: decode player move:", err)
+			return
+		}
+		c.mu.Lock()
+		c.remoteEntities[move.PlayerID] = mgl32.Vec3{move.Pos[0], move.Pos[1], move.Pos[2]}
+		c.mu.Unlock()
+
+	case gnet.PacketEntitySpawn:
+		spawn, err := gnet.DecodeEntitySpawn(payload)
+		if err != nil {
+			log.Println("client: decode entity spawn:", err)
+			return
+		}
+		c.mu.Lock()
+		c.remoteEntities[spawn.EntityID] = mgl32.Vec3{spawn.Pos[0], spawn.Pos[1], spawn.Pos[2]}
+		c.mu.Unlock()
+
+	case gnet.PacketEntityDespawn:
+		despawn, err := gnet.DecodeEntityDespawn(payload)
+		if err != nil {
+			log.Println("client: decode entity despawn:", err)
+			return
+		}
+		c.mu.Lock()
+		delete(c.remoteEntities, despawn.EntityID)
+		c.mu.Unlock()
+	}
+}
+
+// localBlock looks up a block without the spawn-on-miss side effect of
+// World.Block, which would try to reach for a generator the client
+// doesn't have.
+func (c *Client) localBlock(pos mgl32.Vec3) *Block {
+	cp, offset := WorldToChunk(pos)
+	chunk := c.world.chunks.Get(cp)
+	if chunk == nil {
+		return nil
+	}
+	return chunk.Block(offset[0], offset[1], offset[2])
+}
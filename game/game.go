@@ -1,7 +1,9 @@
 package game
 
 import (
+	"flag"
 	"log"
+	"net"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
@@ -37,12 +39,49 @@ type Game struct {
 
 	// physics engine for player movements and collisions
 	physics *PhysicsEngine
+
+	// client owns the network-driven world; its connection is either a
+	// real TCP dial (--client) or the local end of an in-memory pipe to
+	// an in-process server (the default, single-player path)
+	client *Client
+
+	// non-nil only when this process is also hosting the in-process
+	// server for the single-player path
+	server *Server
+
+	// set by Start before Init when running as --client, so Init knows
+	// to dial out instead of spinning up an in-process server
+	netConn net.Conn
 }
 
-// Starts the game.
+// Starts the game. With no flags this hosts an in-process server over
+// an in-memory transport and plays as its only client, so single-player
+// still works with nothing listening on the network. --server runs a
+// headless dedicated server instead; --client connects to one.
 func Start() {
+	serverAddr := flag.String("server", "", "run as a headless dedicated server, listening on this address")
+	clientAddr := flag.String("client", "", "connect to a dedicated server at this address instead of hosting one locally")
+	flag.Parse()
+
+	if *serverAddr != "" {
+		log.Println("Starting dedicated server on", *serverAddr, "...")
+		s := newServer()
+		if err := s.Listen(*serverAddr); err != nil {
+			log.Fatal("server: ", err)
+		}
+		s.Run()
+		return
+	}
+
 	log.Println("Starting game...")
 	g := Game{}
+	if *clientAddr != "" {
+		conn, err := net.Dial("tcp", *clientAddr)
+		if err != nil {
+			log.Fatal("dial server: ", err)
+		}
+		g.netConn = conn
+	}
 	g.Init()
 	g.Run()
 }
@@ -68,8 +107,24 @@ func (g *Game) Init() {
 	g.physics = newPhysicsEngine()
 	g.physics.Register(g.player.body)
 
-	g.world = newWorld(g.shaders.Program("chunk"), atlas)
-	g.world.Init()
+	conn := g.netConn
+	if conn == nil {
+		// single-player: host an in-process server and connect to it
+		// over an in-memory pipe instead of a real socket
+		serverConn, clientConn := net.Pipe()
+		g.server = newServer()
+		g.server.Accept(serverConn)
+		go g.server.Run()
+		conn = clientConn
+	}
+
+	client, err := newClient(conn, g.shaders.Program("chunk"), atlas, "player")
+	if err != nil {
+		log.Fatal("connect to server: ", err)
+	}
+	g.client = client
+	g.world = client.world
+	g.physics.BindWorld(g.world)
 	g.clock = newClock()
 
 	g.SetLookHandler()
@@ -85,6 +140,19 @@ func (g *Game) Init() {
 // Runs the game loop.
 func (g *Game) Run() {
 	defer g.window.Terminate()
+	defer func() {
+		// g.world is only ever the Client's render-side copy, which has
+		// no save subsystem of its own (see newWorld); the world actually
+		// worth persisting is the in-process server's, when we're hosting
+		// one locally. Nothing to do for a pure --client, which hosts no
+		// server world of its own - the remote server persists its own.
+		if g.server == nil {
+			return
+		}
+		if err := g.server.world.Save(); err != nil {
+			log.Println("game: save world on shutdown:", err)
+		}
+	}()
 	g.clock.Start()
 
 	for !g.window.ShouldClose() && !g.window.IsPressed(glfw.KeyQ) {
@@ -100,8 +168,10 @@ func (g *Game) Run() {
 		g.LookBlock()
 		g.HandleInventorySelect()
 
-		// world
-		g.world.SpawnRadius(g.player.body.position)
+		// world: report our movement (the server moves its own copy of
+		// our body by the velocity and streams chunks around that copy),
+		// then process whatever it's sent us so far
+		g.client.SendMove(g.player.body.position, g.player.body.velocity)
 		g.world.ProcessTasks()
 
 		// day/night (uncomment to toggle)
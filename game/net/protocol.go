@@ -0,0 +1,270 @@
+// Package net defines the wire protocol shared by the game's server and
+// client modes: a small set of binary packets, each length-prefixed so
+// they can be framed over any io.ReadWriter - a real TCP connection or
+// an in-memory net.Pipe.
+package net
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// PacketType identifies the payload that follows a frame's header.
+type PacketType byte
+
+const (
+	PacketHandshake PacketType = iota
+	PacketChunkData
+	PacketBlockSet
+	PacketPlayerMove
+	PacketEntitySpawn
+	PacketEntityDespawn
+)
+
+// ProtocolVersion guards against a client and server built from
+// different points in the protocol's history talking past each other.
+const ProtocolVersion = 1
+
+// WritePacket frames t and payload as [4-byte length][1-byte type][payload]
+// and writes it to w.
+func WritePacket(w io.Writer, t PacketType, payload []byte) error {
+	header := make([]byte, 5)
+	binary.LittleEndian.PutUint32(header[0:4], uint32(len(payload)+1))
+	header[4] = byte(t)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// ReadPacket reads one frame written by WritePacket.
+func ReadPacket(r io.Reader) (PacketType, []byte, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.LittleEndian.Uint32(header[0:4])
+	if length == 0 {
+		return 0, nil, fmt.Errorf("net: empty packet frame")
+	}
+
+	body := make([]byte, length-1)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return PacketType(header[4]), body, nil
+}
+
+// Handshake is the first packet either side sends after connecting.
+type Handshake struct {
+	ProtocolVersion uint32
+	PlayerName      string
+}
+
+func (h Handshake) Encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, h.ProtocolVersion)
+	binary.Write(&buf, binary.LittleEndian, uint16(len(h.PlayerName)))
+	buf.WriteString(h.PlayerName)
+	return buf.Bytes()
+}
+
+func DecodeHandshake(payload []byte) (Handshake, error) {
+	r := bytes.NewReader(payload)
+	var h Handshake
+	if err := binary.Read(r, binary.LittleEndian, &h.ProtocolVersion); err != nil {
+		return h, err
+	}
+	var nameLen uint16
+	if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+		return h, err
+	}
+	name := make([]byte, nameLen)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return h, err
+	}
+	h.PlayerName = string(name)
+	return h, nil
+}
+
+// ChunkData carries a chunk's full block grid, zlib-compressed, keyed by
+// its chunk position. Blocks is the caller-defined flattened encoding of
+// the block grid (see game.encodeBlockGrid) - this package only frames
+// and compresses it.
+type ChunkData struct {
+	ChunkX, ChunkY, ChunkZ int32
+	Blocks                 []byte
+}
+
+func (c ChunkData) Encode() ([]byte, error) {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(c.Blocks); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, c.ChunkX)
+	binary.Write(&buf, binary.LittleEndian, c.ChunkY)
+	binary.Write(&buf, binary.LittleEndian, c.ChunkZ)
+	binary.Write(&buf, binary.LittleEndian, uint32(compressed.Len()))
+	buf.Write(compressed.Bytes())
+	return buf.Bytes(), nil
+}
+
+func DecodeChunkData(payload []byte) (ChunkData, error) {
+	r := bytes.NewReader(payload)
+	var c ChunkData
+	binary.Read(r, binary.LittleEndian, &c.ChunkX)
+	binary.Read(r, binary.LittleEndian, &c.ChunkY)
+	binary.Read(r, binary.LittleEndian, &c.ChunkZ)
+
+	var length uint32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return c, err
+	}
+	compressed := make([]byte, length)
+	if _, err := io.ReadFull(r, compressed); err != nil {
+		return c, err
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return c, err
+	}
+	defer zr.Close()
+
+	c.Blocks, err = io.ReadAll(zr)
+	return c, err
+}
+
+// BlockSet reports a single block change at a world position.
+type BlockSet struct {
+	X, Y, Z   int32
+	Active    bool
+	BlockType string
+}
+
+func (b BlockSet) Encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, b.X)
+	binary.Write(&buf, binary.LittleEndian, b.Y)
+	binary.Write(&buf, binary.LittleEndian, b.Z)
+	active := byte(0)
+	if b.Active {
+		active = 1
+	}
+	buf.WriteByte(active)
+	binary.Write(&buf, binary.LittleEndian, uint16(len(b.BlockType)))
+	buf.WriteString(b.BlockType)
+	return buf.Bytes()
+}
+
+func DecodeBlockSet(payload []byte) (BlockSet, error) {
+	r := bytes.NewReader(payload)
+	var b BlockSet
+	binary.Read(r, binary.LittleEndian, &b.X)
+	binary.Read(r, binary.LittleEndian, &b.Y)
+	binary.Read(r, binary.LittleEndian, &b.Z)
+	active, err := r.ReadByte()
+	if err != nil {
+		return b, err
+	}
+	b.Active = active != 0
+
+	var typeLen uint16
+	if err := binary.Read(r, binary.LittleEndian, &typeLen); err != nil {
+		return b, err
+	}
+	blockType := make([]byte, typeLen)
+	if _, err := io.ReadFull(r, blockType); err != nil {
+		return b, err
+	}
+	b.BlockType = string(blockType)
+	return b, nil
+}
+
+// PlayerMove reports a player's authoritative (server) or predicted
+// (client) position and velocity.
+type PlayerMove struct {
+	PlayerID uint32
+	Pos      [3]float32
+	Vel      [3]float32
+}
+
+func (m PlayerMove) Encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, m.PlayerID)
+	binary.Write(&buf, binary.LittleEndian, m.Pos)
+	binary.Write(&buf, binary.LittleEndian, m.Vel)
+	return buf.Bytes()
+}
+
+func DecodePlayerMove(payload []byte) (PlayerMove, error) {
+	r := bytes.NewReader(payload)
+	var m PlayerMove
+	binary.Read(r, binary.LittleEndian, &m.PlayerID)
+	binary.Read(r, binary.LittleEndian, &m.Pos)
+	err := binary.Read(r, binary.LittleEndian, &m.Vel)
+	return m, err
+}
+
+// EntitySpawn announces a new entity (typically another player) to a client.
+type EntitySpawn struct {
+	EntityID uint32
+	Kind     string
+	Pos      [3]float32
+}
+
+func (e EntitySpawn) Encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, e.EntityID)
+	binary.Write(&buf, binary.LittleEndian, uint16(len(e.Kind)))
+	buf.WriteString(e.Kind)
+	binary.Write(&buf, binary.LittleEndian, e.Pos)
+	return buf.Bytes()
+}
+
+func DecodeEntitySpawn(payload []byte) (EntitySpawn, error) {
+	r := bytes.NewReader(payload)
+	var e EntitySpawn
+	binary.Read(r, binary.LittleEndian, &e.EntityID)
+	var kindLen uint16
+	if err := binary.Read(r, binary.LittleEndian, &kindLen); err != nil {
+		return e, err
+	}
+	kind := make([]byte, kindLen)
+	if _, err := io.ReadFull(r, kind); err != nil {
+		return e, err
+	}
+	e.Kind = string(kind)
+	err := binary.Read(r, binary.LittleEndian, &e.Pos)
+	return e, err
+}
+
+// EntityDespawn announces that an entity has left the world.
+type EntityDespawn struct {
+	EntityID uint32
+}
+
+func (e EntityDespawn) Encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, e.EntityID)
+	return buf.Bytes()
+}
+
+func DecodeEntityDespawn(payload []byte) (EntityDespawn, error) {
+	r := bytes.NewReader(payload)
+	var e EntityDespawn
+	err := binary.Read(r, binary.LittleEndian, &e.EntityID)
+	return e, err
+}
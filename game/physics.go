@@ -0,0 +1,159 @@
+package game
+
+import (
+	"sync"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Body is a simple AABB rigid body tracked by the PhysicsEngine.
+// position is the AABB's center, halfExtents its half-size per axis.
+//
+// mu guards position/velocity: in single-player they're only ever
+// touched by the GL-thread tick loop, but a Server's bodies are also
+// written from each connection's read goroutine (see
+// Server.handlePacket), concurrently with PhysicsEngine.Tick running on
+// the ticker goroutine.
+type Body struct {
+	mu sync.Mutex
+
+	position    mgl32.Vec3
+	velocity    mgl32.Vec3
+	halfExtents mgl32.Vec3
+}
+
+func newBody(halfExtents mgl32.Vec3) *Body {
+	return &Body{halfExtents: halfExtents}
+}
+
+// Position returns the body's current position.
+func (b *Body) Position() mgl32.Vec3 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.position
+}
+
+// SetVelocity updates the body's velocity, e.g. from a client's reported
+// movement input.
+func (b *Body) SetVelocity(v mgl32.Vec3) {
+	b.mu.Lock()
+	b.velocity = v
+	b.mu.Unlock()
+}
+
+const gravity = -20.0
+
+// PhysicsEngine steps registered bodies forward each tick and resolves
+// collisions against the world's blocks.
+type PhysicsEngine struct {
+	world *World
+
+	mu     sync.Mutex
+	bodies []*Body
+}
+
+func newPhysicsEngine() *PhysicsEngine {
+	return &PhysicsEngine{}
+}
+
+// BindWorld gives the engine access to the chunk grid for collision
+// queries. Must be called once, after the world exists, before Tick.
+func (p *PhysicsEngine) BindWorld(w *World) {
+	p.world = w
+}
+
+// Register adds a body to the simulation. Safe to call concurrently with
+// Tick, e.g. from a Server accepting a new connection while the tick
+// loop is already running.
+func (p *PhysicsEngine) Register(b *Body) {
+	p.mu.Lock()
+	p.bodies = append(p.bodies, b)
+	p.mu.Unlock()
+}
+
+// Tick advances every registered body by delta seconds and resolves
+// collisions against nearby blocks.
+//
+// Candidate blocks come straight from the chunk grid: for each body we
+// compute the chunk cells its swept AABB overlaps and read the block
+// array out of those chunks directly via World.BlocksInAABB, instead of
+// calling World.Block per candidate cell, which would spawn chunks as a
+// side effect of a physics query.
+func (p *PhysicsEngine) Tick(delta float32) {
+	p.mu.Lock()
+	bodies := append([]*Body(nil), p.bodies...)
+	p.mu.Unlock()
+
+	for _, b := range bodies {
+		b.mu.Lock()
+		b.velocity = b.velocity.Add(mgl32.Vec3{0, gravity * delta, 0})
+		next := b.position.Add(b.velocity.Mul(delta))
+
+		min, max := sweptAABB(b, next)
+		blocks := p.world.BlocksInAABB(min, max)
+
+		b.position, b.velocity = resolveCollisions(b, next, blocks)
+		b.mu.Unlock()
+	}
+}
+
+// sweptAABB returns the bounding box a body occupies between its
+// current and next position, expanded by its half-extents, so the
+// broadphase doesn't miss blocks the body passes through mid-tick.
+func sweptAABB(b *Body, next mgl32.Vec3) (min, max mgl32.Vec3) {
+	lo := componentMin(b.position, next).Sub(b.halfExtents)
+	hi := componentMax(b.position, next).Add(b.halfExtents)
+	return lo, hi
+}
+
+// resolveCollisions pushes next out of any overlapping block along y,
+// the common "standing on ground" case, zeroing vertical velocity on
+// contact. A full resolver would pick the axis of least penetration;
+// this covers gravity/ground collision, the dominant case for now.
+func resolveCollisions(b *Body, next mgl32.Vec3, blocks []*Block) (mgl32.Vec3, mgl32.Vec3) {
+	velocity := b.velocity
+	min := next.Sub(b.halfExtents)
+	max := next.Add(b.halfExtents)
+
+	for _, block := range blocks {
+		bmin := block.WorldPos()
+		bmax := bmin.Add(mgl32.Vec3{1, 1, 1})
+
+		if min.X() >= bmax.X() || max.X() <= bmin.X() ||
+			min.Y() >= bmax.Y() || max.Y() <= bmin.Y() ||
+			min.Z() >= bmax.Z() || max.Z() <= bmin.Z() {
+			continue // no overlap
+		}
+
+		if velocity.Y() <= 0 {
+			next[1] = bmax.Y() + b.halfExtents.Y()
+			velocity[1] = 0
+			min = next.Sub(b.halfExtents)
+			max = next.Add(b.halfExtents)
+		}
+	}
+
+	return next, velocity
+}
+
+func componentMin(a, b mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.Vec3{min32(a.X(), b.X()), min32(a.Y(), b.Y()), min32(a.Z(), b.Z())}
+}
+
+func componentMax(a, b mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.Vec3{max32(a.X(), b.X()), max32(a.Y(), b.Y()), max32(a.Z(), b.Z())}
+}
+
+func min32(a, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max32(a, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,430 @@
+package game
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// region files group chunks the way Cuberite/vanilla Minecraft do, so a
+// player exploring in one direction only ever touches a handful of files.
+const (
+	regionSize  = 16 // chunks per region file, per axis
+	saveDir     = "./save"
+	flushPeriod = 10 * time.Second
+)
+
+// regionEntry is one slot of a region file's header table: where the
+// chunk's payload lives and when it was last written.
+type regionEntry struct {
+	offset    uint32
+	length    uint32
+	timestamp int64
+}
+
+// recordHeaderSize is the fixed-size header Flush writes immediately
+// before each chunk's payload: local chunk coord (2x int32), payload
+// length (uint32) and timestamp (int64). scan() replays this stream on
+// startup to rebuild the in-memory header table without needing a
+// separate on-disk index.
+const recordHeaderSize = 4 + 4 + 4 + 8
+
+// chunkDiff is the on-disk payload for a single chunk: a bitmap of the
+// blocks that differ from the deterministic generator output, plus the
+// type string for each set bit. Chunks that were never touched are never
+// written at all: no payload and no header record.
+type chunkDiff struct {
+	bitmap []byte // chunkWidth*chunkHeight*chunkWidth bits, row-major x,y,z
+	active []bool
+	types  []string
+}
+
+// WorldSave persists chunk diffs to fixed-size region files and reloads
+// them on demand. Untouched chunks never hit disk.
+type WorldSave struct {
+	dir string
+
+	mu      sync.Mutex
+	regions map[[2]int32]map[[2]int32]regionEntry // region coord -> local chunk coord -> entry
+}
+
+func newWorldSave(dir string) *WorldSave {
+	os.MkdirAll(dir, 0o755)
+	return &WorldSave{
+		dir:     dir,
+		regions: make(map[[2]int32]map[[2]int32]regionEntry),
+	}
+}
+
+// regionCoord splits a chunk position into the region file it belongs to
+// and its local slot inside that region.
+func regionCoord(pos mgl32.Vec3) (region, local [2]int32) {
+	cx := int32(pos.X()) / int32(chunkWidth)
+	cz := int32(pos.Z()) / int32(chunkWidth)
+	rx := floorDivInt32(cx, regionSize)
+	rz := floorDivInt32(cz, regionSize)
+	lx := cx - rx*regionSize
+	lz := cz - rz*regionSize
+	return [2]int32{rx, rz}, [2]int32{lx, lz}
+}
+
+func floorDivInt32(a, b int32) int32 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func (s *WorldSave) regionPath(region [2]int32) string {
+	return filepath.Join(s.dir, fmt.Sprintf("r.%d.%d.region", region[0], region[1]))
+}
+
+// Load reads a chunk's diff from its region file, if one was ever
+// written, and applies it on top of the generator baseline already
+// sitting in the chunk's store. Returns false if nothing was on disk,
+// in which case the chunk is left as pure generator output.
+func (s *WorldSave) Load(chunk *Chunk) bool {
+	region, local := regionCoord(chunk.pos)
+
+	s.mu.Lock()
+	table, ok := s.regions[region]
+	var entry regionEntry
+	if ok {
+		entry, ok = table[local]
+	}
+	s.mu.Unlock()
+	if !ok || entry.length == 0 {
+		return false
+	}
+
+	f, err := os.Open(s.regionPath(region))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	buf := make([]byte, entry.length)
+	if _, err := f.ReadAt(buf, int64(entry.offset)); err != nil {
+		return false
+	}
+
+	diff, err := decodeChunkDiff(buf)
+	if err != nil {
+		return false
+	}
+
+	applyChunkDiff(chunk, diff)
+	return true
+}
+
+// Flush diffs a chunk against the generator baseline and, if anything
+// changed, compresses and writes it to its region file. Chunks that
+// still match the generator exactly are skipped so they cost nothing.
+func (s *WorldSave) Flush(chunk *Chunk, generator *WorldGenerator) error {
+	if !chunk.Dirty() {
+		return nil
+	}
+
+	diff := diffChunk(chunk, generator)
+	if diff == nil {
+		return nil
+	}
+
+	payload, err := encodeChunkDiff(diff)
+	if err != nil {
+		return err
+	}
+
+	region, local := regionCoord(chunk.pos)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.regionPath(region)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	recordStart, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+	var header bytes.Buffer
+	binary.Write(&header, binary.LittleEndian, local[0])
+	binary.Write(&header, binary.LittleEndian, local[1])
+	binary.Write(&header, binary.LittleEndian, uint32(len(payload)))
+	binary.Write(&header, binary.LittleEndian, timestamp)
+	if _, err := f.Write(header.Bytes()); err != nil {
+		return err
+	}
+	if _, err := f.Write(payload); err != nil {
+		return err
+	}
+
+	table, ok := s.regions[region]
+	if !ok {
+		table = make(map[[2]int32]regionEntry)
+		s.regions[region] = table
+	}
+	table[local] = regionEntry{
+		offset:    uint32(recordStart) + recordHeaderSize,
+		length:    uint32(len(payload)),
+		timestamp: timestamp,
+	}
+
+	chunk.ClearDirty()
+	return nil
+}
+
+// StartFlusher launches a background goroutine that periodically queues
+// a flush of every dirty chunk onto the world's TaskQueue, so the actual
+// disk writes happen alongside the other deferred work rather than on
+// a timer racing the GL thread.
+func (w *World) StartFlusher() {
+	go func() {
+		ticker := time.NewTicker(flushPeriod)
+		defer ticker.Stop()
+		for range ticker.C {
+			w.tasks.Queue(w.flushDirty)
+		}
+	}()
+}
+
+func (w *World) flushDirty() {
+	for _, c := range w.chunks.All() {
+		w.loader.Flush(c)
+	}
+}
+
+// Save flushes every loaded chunk to disk immediately, e.g. on shutdown.
+// A no-op for a World with no save subsystem (see Load).
+func (w *World) Save() error {
+	if w.save == nil {
+		return nil
+	}
+	for _, c := range w.chunks.All() {
+		if err := w.save.Flush(c, w.generator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load points the world's save subsystem at an existing save directory,
+// rebuilding the region header tables from the files already on disk.
+func (w *World) Load(path string) error {
+	w.save = newWorldSave(path)
+	w.loader.save = w.save
+	return w.save.scan()
+}
+
+// scan rebuilds the in-memory header tables by reading the (local chunk
+// coord, length, timestamp) records Flush writes back out of every
+// region file already on disk, without re-reading the payloads themselves.
+func (s *WorldSave) scan() error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		region, ok := parseRegionFilename(e.Name())
+		if !ok {
+			continue
+		}
+		if err := s.scanRegionFile(region); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRegionFilename extracts a region coordinate from a "r.<x>.<z>.region"
+// filename, the inverse of WorldSave.regionPath.
+func parseRegionFilename(name string) (region [2]int32, ok bool) {
+	var rx, rz int32
+	if _, err := fmt.Sscanf(name, "r.%d.%d.region", &rx, &rz); err != nil {
+		return [2]int32{}, false
+	}
+	return [2]int32{rx, rz}, true
+}
+
+// scanRegionFile replays a region file's record stream - each record is
+// a fixed-size header (see recordHeaderSize) immediately followed by its
+// payload - to rebuild that region's header table.
+func (s *WorldSave) scanRegionFile(region [2]int32) error {
+	f, err := os.Open(s.regionPath(region))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	table := make(map[[2]int32]regionEntry)
+	var pos int64
+
+	for {
+		header := make([]byte, recordHeaderSize)
+		if _, err := io.ReadFull(f, header); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		r := bytes.NewReader(header)
+		var lx, lz int32
+		var length uint32
+		var timestamp int64
+		binary.Read(r, binary.LittleEndian, &lx)
+		binary.Read(r, binary.LittleEndian, &lz)
+		binary.Read(r, binary.LittleEndian, &length)
+		binary.Read(r, binary.LittleEndian, &timestamp)
+
+		payloadOffset := pos + recordHeaderSize
+		if _, err := f.Seek(int64(length), io.SeekCurrent); err != nil {
+			return err
+		}
+
+		table[[2]int32{lx, lz}] = regionEntry{
+			offset:    uint32(payloadOffset),
+			length:    length,
+			timestamp: timestamp,
+		}
+		pos = payloadOffset + int64(length)
+	}
+
+	s.mu.Lock()
+	s.regions[region] = table
+	s.mu.Unlock()
+	return nil
+}
+
+func diffChunk(chunk *Chunk, generator *WorldGenerator) *chunkDiff {
+	baseline := generator.Terrain(chunk.pos)
+
+	bits := make([]byte, (chunkWidth*chunkHeight*chunkWidth+7)/8)
+	var active []bool
+	var types []string
+	changed := false
+
+	i := 0
+	for x := 0; x < chunkWidth; x++ {
+		for y := 0; y < chunkHeight; y++ {
+			for z := 0; z < chunkWidth; z++ {
+				cur := chunk.Block(x, y, z)
+				curActive, curType := cur.Active(), cur.BlockType()
+				want := baseline.Blocks[x][y][z]
+				if curActive != want.Active || curType != want.BlockType {
+					bits[i/8] |= 1 << uint(i%8)
+					active = append(active, curActive)
+					types = append(types, curType)
+					changed = true
+				}
+				i++
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return &chunkDiff{bitmap: bits, active: active, types: types}
+}
+
+func applyChunkDiff(chunk *Chunk, diff *chunkDiff) {
+	idx := 0
+	i := 0
+	for x := 0; x < chunkWidth; x++ {
+		for y := 0; y < chunkHeight; y++ {
+			for z := 0; z < chunkWidth; z++ {
+				if diff.bitmap[i/8]&(1<<uint(i%8)) != 0 {
+					chunk.store.Set(x, y, z, diff.active[idx], diff.types[idx])
+					idx++
+				}
+				i++
+			}
+		}
+	}
+}
+
+func encodeChunkDiff(diff *chunkDiff) ([]byte, error) {
+	var raw bytes.Buffer
+	binary.Write(&raw, binary.LittleEndian, uint32(len(diff.bitmap)))
+	raw.Write(diff.bitmap)
+	binary.Write(&raw, binary.LittleEndian, uint32(len(diff.types)))
+	for i, t := range diff.types {
+		a := byte(0)
+		if diff.active[i] {
+			a = 1
+		}
+		raw.WriteByte(a)
+		binary.Write(&raw, binary.LittleEndian, uint16(len(t)))
+		raw.WriteString(t)
+	}
+
+	var out bytes.Buffer
+	zw := zlib.NewWriter(&out)
+	if _, err := zw.Write(raw.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func decodeChunkDiff(payload []byte) (*chunkDiff, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bytes.NewReader(raw)
+	var bitmapLen uint32
+	binary.Read(r, binary.LittleEndian, &bitmapLen)
+	bitmap := make([]byte, bitmapLen)
+	io.ReadFull(r, bitmap)
+
+	var count uint32
+	binary.Read(r, binary.LittleEndian, &count)
+	active := make([]bool, count)
+	types := make([]string, count)
+	for i := uint32(0); i < count; i++ {
+		var a byte
+		binary.Read(r, binary.LittleEndian, &a)
+		active[i] = a != 0
+
+		var strLen uint16
+		binary.Read(r, binary.LittleEndian, &strLen)
+		buf := make([]byte, strLen)
+		io.ReadFull(r, buf)
+		types[i] = string(buf)
+	}
+
+	return &chunkDiff{bitmap: bitmap, active: active, types: types}, nil
+}
@@ -0,0 +1,297 @@
+package game
+
+import (
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	gnet "github.com/nasa03/minecraft/game/net"
+)
+
+// tickRate is the fixed rate the authoritative simulation steps at,
+// independent of any client's render framerate.
+const tickRate = 20
+
+// maxPlayerSpeed clamps a client's reported velocity, blocks/sec. The
+// server never accepts a client's reported position directly - only a
+// clamped velocity, which PhysicsEngine.Tick then integrates against its
+// own gravity and collision resolution - so a client can't just claim to
+// be somewhere, including through a wall.
+const maxPlayerSpeed = 12.0
+
+// Server owns the authoritative World, PhysicsEngine and TaskQueue and
+// runs headless: no GL, no Window, nothing that needs a display. Instead
+// of one local player driving World.SpawnRadius, it runs SpawnRadius
+// around every connected player and streams each of them only the
+// chunks inside their own visible radius.
+type Server struct {
+	world   *World
+	physics *PhysicsEngine
+
+	mu           sync.Mutex
+	clients      map[uint32]*serverClient
+	nextClientID uint32
+}
+
+// serverClient is one connected player: their body in the physics
+// simulation, their connection, and which chunks have already been
+// streamed to them so Tick only ever sends the delta.
+type serverClient struct {
+	id   uint32
+	name string
+	conn net.Conn
+	body *Body
+
+	mu   sync.Mutex
+	sent map[ChunkPos]bool
+}
+
+func newServer() *Server {
+	s := &Server{clients: make(map[uint32]*serverClient)}
+	s.world = newWorld(nil, nil)
+	if err := s.world.Load(saveDir); err != nil {
+		log.Println("server: load save:", err)
+	}
+	s.world.Init()
+	s.physics = newPhysicsEngine()
+	s.physics.BindWorld(s.world)
+	return s
+}
+
+// Listen accepts TCP connections on addr until the listener errors.
+func (s *Server) Listen(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go s.acceptLoop(ln)
+	return nil
+}
+
+func (s *Server) acceptLoop(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Println("server: accept:", err)
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Accept registers an already-established connection, e.g. the
+// in-memory pipe the single-player path uses to talk to its own
+// in-process server.
+func (s *Server) Accept(conn net.Conn) {
+	go s.handleConn(conn)
+}
+
+// Run steps the simulation at tickRate until the process exits.
+func (s *Server) Run() {
+	ticker := time.NewTicker(time.Second / tickRate)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.Tick(1.0 / tickRate)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	t, payload, err := gnet.ReadPacket(conn)
+	if err != nil || t != gnet.PacketHandshake {
+		conn.Close()
+		return
+	}
+	hs, err := gnet.DecodeHandshake(payload)
+	if err != nil || hs.ProtocolVersion != gnet.ProtocolVersion {
+		conn.Close()
+		return
+	}
+
+	s.mu.Lock()
+	s.nextClientID++
+	c := &serverClient{
+		id:   s.nextClientID,
+		name: hs.PlayerName,
+		conn: conn,
+		body: newBody(mgl32.Vec3{0.4, 0.9, 0.4}),
+		sent: make(map[ChunkPos]bool),
+	}
+	s.clients[c.id] = c
+	s.mu.Unlock()
+
+	s.physics.Register(c.body)
+	gnet.WritePacket(conn, gnet.PacketHandshake, gnet.Handshake{
+		ProtocolVersion: gnet.ProtocolVersion,
+		PlayerName:      hs.PlayerName,
+	}.Encode())
+
+	log.Printf("server: %s connected as client %d", hs.PlayerName, c.id)
+	s.announce(c)
+
+	for {
+		t, payload, err := gnet.ReadPacket(conn)
+		if err != nil {
+			s.disconnect(c)
+			return
+		}
+		s.handlePacket(c, t, payload)
+	}
+}
+
+func (s *Server) disconnect(c *serverClient) {
+	s.mu.Lock()
+	delete(s.clients, c.id)
+	s.mu.Unlock()
+	c.conn.Close()
+	log.Printf("server: client %d disconnected", c.id)
+
+	s.broadcast(gnet.PacketEntityDespawn, gnet.EntityDespawn{EntityID: c.id}.Encode(), nil)
+}
+
+// announce tells every already-connected client about the newly
+// connected one, and the newly connected one about every client already
+// here, so both sides can render the other's presence.
+func (s *Server) announce(c *serverClient) {
+	s.mu.Lock()
+	others := make([]*serverClient, 0, len(s.clients))
+	for _, other := range s.clients {
+		if other != c {
+			others = append(others, other)
+		}
+	}
+	s.mu.Unlock()
+
+	newSpawn := gnet.EntitySpawn{EntityID: c.id, Kind: "player", Pos: vec3Array(c.body.Position())}.Encode()
+	for _, other := range others {
+		if err := gnet.WritePacket(other.conn, gnet.PacketEntitySpawn, newSpawn); err != nil {
+			log.Println("server: announce to client", other.id, ":", err)
+			continue
+		}
+
+		existing := gnet.EntitySpawn{EntityID: other.id, Kind: "player", Pos: vec3Array(other.body.Position())}.Encode()
+		if err := gnet.WritePacket(c.conn, gnet.PacketEntitySpawn, existing); err != nil {
+			log.Println("server: announce to client", c.id, ":", err)
+		}
+	}
+}
+
+// broadcast sends a packet to every connected client except exclude
+// (pass nil to include everyone), e.g. relaying one client's block edit
+// to the others so the world stays in sync across a live session.
+func (s *Server) broadcast(t gnet.PacketType, payload []byte, exclude *serverClient) {
+	s.mu.Lock()
+	clients := make([]*serverClient, 0, len(s.clients))
+	for _, other := range s.clients {
+		if other != exclude {
+			clients = append(clients, other)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, other := range clients {
+		if err := gnet.WritePacket(other.conn, t, payload); err != nil {
+			log.Println("server: broadcast to client", other.id, ":", err)
+		}
+	}
+}
+
+func vec3Array(v mgl32.Vec3) [3]float32 {
+	return [3]float32{v.X(), v.Y(), v.Z()}
+}
+
+func (s *Server) handlePacket(c *serverClient, t gnet.PacketType, payload []byte) {
+	switch t {
+	case gnet.PacketPlayerMove:
+		move, err := gnet.DecodePlayerMove(payload)
+		if err != nil {
+			return
+		}
+		vel := mgl32.Vec3{move.Vel[0], move.Vel[1], move.Vel[2]}
+		if l := vel.Len(); l > maxPlayerSpeed {
+			vel = vel.Mul(maxPlayerSpeed / l)
+		}
+		c.body.SetVelocity(vel)
+	case gnet.PacketBlockSet:
+		set, err := gnet.DecodeBlockSet(payload)
+		if err != nil {
+			return
+		}
+		pos := mgl32.Vec3{float32(set.X), float32(set.Y), float32(set.Z)}
+		if b := s.world.Block(pos); b != nil {
+			b.Set(set.Active, set.BlockType)
+		}
+		s.broadcast(gnet.PacketBlockSet, payload, c)
+	}
+}
+
+// Tick advances the simulation one step and streams world updates to
+// every connected client.
+func (s *Server) Tick(delta float32) {
+	s.world.ProcessTasks()
+	s.physics.Tick(delta)
+
+	s.mu.Lock()
+	clients := make([]*serverClient, 0, len(s.clients))
+	for _, c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		s.world.SpawnRadius(c.body.Position())
+		s.streamChunks(c)
+	}
+
+	s.broadcastMoves(clients)
+}
+
+// broadcastMoves sends every connected client's current authoritative
+// position to every other client, every tick. Without this, a remote
+// player is only ever placed once, at announce time, and then appears
+// frozen for the rest of the session even though PhysicsEngine keeps
+// moving their body.
+func (s *Server) broadcastMoves(clients []*serverClient) {
+	for _, c := range clients {
+		payload := gnet.PlayerMove{PlayerID: c.id, Pos: vec3Array(c.body.Position())}.Encode()
+		s.broadcast(gnet.PacketPlayerMove, payload, c)
+	}
+}
+
+// streamChunks sends a client every chunk in its visible radius it
+// hasn't already received. Each chunk goes to a given client at most
+// once per connection; the server sends nothing when a chunk leaves the
+// radius, and the client drops far chunks itself in World.NearChunks.
+func (s *Server) streamChunks(c *serverClient) {
+	for _, chunk := range s.world.NearChunks(c.body.Position()) {
+		cp := chunkPosFromOrigin(chunk.pos)
+
+		c.mu.Lock()
+		alreadySent := c.sent[cp]
+		c.mu.Unlock()
+		if alreadySent {
+			continue
+		}
+
+		data := gnet.ChunkData{
+			ChunkX: int32(chunk.pos.X()),
+			ChunkY: int32(chunk.pos.Y()),
+			ChunkZ: int32(chunk.pos.Z()),
+			Blocks: encodeBlockGrid(chunk),
+		}
+		payload, err := data.Encode()
+		if err != nil {
+			log.Println("server: encode chunk:", err)
+			continue
+		}
+		if err := gnet.WritePacket(c.conn, gnet.PacketChunkData, payload); err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		c.sent[cp] = true
+		c.mu.Unlock()
+	}
+}
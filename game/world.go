@@ -3,18 +3,22 @@ package game
 import (
 	"math"
 	"math/rand"
+	"sync"
 
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-// TODO: persist game
-
 // World holds the terrain, map and manages entity lifecycles.
 type World struct {
 	atlas *TextureAtlas
 
 	// chunk map, provides lookup by location
-	chunks VecMap[Chunk]
+	chunks *ChunkMap
+
+	// serializes the check-then-spawn in Block against concurrent
+	// callers - single-player only ever called Block from the GL thread,
+	// but the server calls it from every connection's goroutine
+	spawnMu sync.Mutex
 
 	// shader program that draws the chunks
 	chunkShader *Shader
@@ -24,6 +28,12 @@ type World struct {
 
 	// queues tasks allowing defered processing
 	tasks *TaskQueue
+
+	// persists chunk diffs to region files and reloads them on spawn
+	save *WorldSave
+
+	// drives chunk generation and persistence through tasks
+	loader *ChunkLoader
 }
 
 const (
@@ -42,13 +52,19 @@ const (
 	seed          = 10
 )
 
+// newWorld builds a World with no save subsystem: chunks generate but
+// never persist or reload until a caller opts in with Load. A Client's
+// World stays this way permanently - it's never driven by a generator in
+// practice, only fed by the server, so a save directory would just be
+// wasted I/O for a copy that's never read back.
 func newWorld(chunkShader *Shader, atlas *TextureAtlas) *World {
 	w := &World{}
 	w.chunkShader = chunkShader
-	w.chunks = newVecMap[Chunk]()
+	w.chunks = newChunkMap()
 	w.atlas = atlas
 	w.generator = newWorldGenerator(seed)
 	w.tasks = newQueue()
+	w.loader = newChunkLoader(w.generator, nil, w.tasks, w)
 	return w
 }
 
@@ -60,6 +76,7 @@ func (w *World) Init() {
 			w.SpawnChunk(p)
 		}
 	}
+	w.StartFlusher()
 }
 
 // Processes tasks queued.
@@ -82,24 +99,19 @@ func (w *World) SpawnChunk(pos mgl32.Vec3) *Chunk {
 		panic("invalid chunk position")
 	}
 
-	// init chunk, attribs, pointers and save
-	chunk := newChunk(w.chunkShader, w.atlas, pos)
-	w.chunks.Set(pos, chunk)
-	s := w.generator.Terrain(chunk.pos)
-	chunk.Init(s)
-
-	w.tasks.Queue(func() {
-		w.SpawnTrees(chunk)
-		chunk.Buffer()
-	})
-
-	chunk.Buffer()
+	// loader generates terrain, applies any persisted diff and, only if
+	// nothing was persisted, queues decoration + a mesh rebuild; the
+	// first Buffer() runs sync so the chunk is immediately visible
+	chunk := w.loader.Spawn(w.chunkShader, w.atlas, pos, w.SpawnTrees)
+	w.chunks.Set(chunkPosFromOrigin(pos), chunk)
 	return chunk
 }
 
 // Despawns the chunk and destroys the data on gpu.
+// Flushes the chunk to disk first if it diverges from the generator output.
 func (w *World) DespawnChunk(c *Chunk) {
-	w.chunks.Delete(c.pos)
+	w.loader.Flush(c)
+	w.chunks.Delete(chunkPosFromOrigin(c.pos))
 	c.Destroy()
 }
 
@@ -108,7 +120,7 @@ func (w *World) DespawnChunk(c *Chunk) {
 func (w *World) Ground(x, z float32) *Block {
 	for y := chunkHeight - 1; y >= 0; y-- {
 		b := w.Block(mgl32.Vec3{x, float32(y), z})
-		if b != nil && b.active {
+		if b != nil && b.Active() {
 			return b
 		}
 	}
@@ -159,42 +171,77 @@ func (w *World) SpawnRadius(center mgl32.Vec3) {
 // This takes any position in the world, including non-round postions.
 // Will spawn chunk if it doesnt exist yet.
 func (w *World) Block(pos mgl32.Vec3) *Block {
-	floor := func(v float32) int {
-		return int(math.Floor(float64(v)))
-	}
-	x, y, z := floor(pos.X()), floor(pos.Y()), floor(pos.Z())
-
-	// remainder will be the offset inside chunk
-	xoffset := x % chunkWidth
-	yoffset := y % chunkHeight
-	zoffset := z % chunkWidth
-
-	// if the offsets are negative we flip
-	// because chunk origins are at the lower end corners
-	if xoffset < 0 {
-		// offset = chunkSize - (-offset)
-		xoffset = chunkWidth + xoffset
-	}
-	if yoffset < 0 {
-		yoffset = chunkHeight + yoffset
-	}
-	if zoffset < 0 {
-		zoffset = chunkWidth + zoffset
+	cp, offset := WorldToChunk(pos)
+	chunk := w.chunks.Get(cp)
+	if chunk == nil {
+		// check-then-spawn has to be serialized: without spawnMu, two
+		// callers racing on the same unloaded position both observe nil,
+		// both build a chunk, and the second ChunkMap.Set silently
+		// clobbers the first's.
+		w.spawnMu.Lock()
+		chunk = w.chunks.Get(cp)
+		if chunk == nil {
+			chunk = w.SpawnChunk(cp.Origin())
+		}
+		w.spawnMu.Unlock()
 	}
 
-	// get the chunk origin position
-	startX := x - xoffset
-	startY := y - yoffset
-	startZ := z - zoffset
+	return chunk.Block(offset[0], offset[1], offset[2])
+}
 
-	chunkPos := mgl32.Vec3{float32(startX), float32(startY), float32(startZ)}
-	chunk := w.chunks.Get(chunkPos)
-	if chunk == nil {
-		chunk = w.SpawnChunk(chunkPos)
+// BlocksInAABB returns every active block whose unit cube overlaps the
+// given world-space AABB. It walks chunks by integer coords and reads
+// their block arrays directly, skipping chunks that aren't loaded
+// rather than spawning them - this is what lets physics queries stay
+// side-effect free.
+func (w *World) BlocksInAABB(min, max mgl32.Vec3) []*Block {
+	minCP, _ := WorldToChunk(min)
+	maxCP, _ := WorldToChunk(max)
+
+	var out []*Block
+	for cx := minCP.X; cx <= maxCP.X; cx++ {
+		for cy := minCP.Y; cy <= maxCP.Y; cy++ {
+			for cz := minCP.Z; cz <= maxCP.Z; cz++ {
+				cp := ChunkPos{X: cx, Y: cy, Z: cz}
+				chunk := w.chunks.Get(cp)
+				if chunk == nil {
+					continue
+				}
+
+				origin := cp.Origin()
+				lx0, lx1 := localRange(min.X(), max.X(), origin.X(), chunkWidth)
+				ly0, ly1 := localRange(min.Y(), max.Y(), origin.Y(), chunkHeight)
+				lz0, lz1 := localRange(min.Z(), max.Z(), origin.Z(), chunkWidth)
+
+				for x := lx0; x <= lx1; x++ {
+					for y := ly0; y <= ly1; y++ {
+						for z := lz0; z <= lz1; z++ {
+							b := chunk.Block(x, y, z)
+							if b.Active() {
+								out = append(out, b)
+							}
+						}
+					}
+				}
+			}
+		}
 	}
 
-	block := chunk.blocks[xoffset][yoffset][zoffset]
-	return block
+	return out
+}
+
+// localRange clips a world-space [worldMin, worldMax] range to the
+// 0..dim-1 in-chunk block indices it overlaps for a chunk at chunkOrigin.
+func localRange(worldMin, worldMax, chunkOrigin float32, dim int) (int, int) {
+	lo := int(math.Floor(float64(worldMin - chunkOrigin)))
+	hi := int(math.Floor(float64(worldMax - chunkOrigin)))
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > dim-1 {
+		hi = dim - 1
+	}
+	return lo, hi
 }
 
 // Spawns tress on a chunk.
@@ -219,18 +266,15 @@ func (w *World) SpawnTrees(chunk *Chunk) {
 			// trunk
 			for i := 1; i < int(trunkHeight); i++ {
 				block := w.Block(base.Add(mgl32.Vec3{0, float32(i), 0}))
-				block.active = true
+				blockType := "wood"
 				if biome < 0.4 {
-					block.blockType = "cactus"
-				} else {
-					if int(prob*100)%2 == 0 {
-						block.blockType = "dark-wood"
-					} else if int(prob*1000)%2 == 0 {
-						block.blockType = "white-wood"
-					} else {
-						block.blockType = "wood"
-					}
+					blockType = "cactus"
+				} else if int(prob*100)%2 == 0 {
+					blockType = "dark-wood"
+				} else if int(prob*1000)%2 == 0 {
+					blockType = "white-wood"
 				}
+				block.Set(true, blockType)
 			}
 
 			// dont draw leaves
@@ -256,8 +300,7 @@ func (w *World) SpawnTrees(chunk *Chunk) {
 
 						if x == int(layerWidth)/2 && z == int(layerWidth)/2 {
 							if y < int(leavesHeight)-1 {
-								block.active = true
-								block.blockType = "wood"
+								block.Set(true, "wood")
 							}
 
 							if !small {
@@ -265,8 +308,7 @@ func (w *World) SpawnTrees(chunk *Chunk) {
 							}
 						}
 
-						block.active = true
-						block.blockType = "leaves"
+						block.Set(true, "leaves")
 					}
 				}
 			}